@@ -0,0 +1,241 @@
+// Package config loads historik's user-configurable exclusion and
+// redaction rules from $XDG_CONFIG_HOME/historik/config.toml.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// DedupeMode selects how two history entries are compared for
+// deduplication.
+type DedupeMode string
+
+const (
+	// DedupeExact treats entries as duplicates only if their commands
+	// are byte-for-byte identical.
+	DedupeExact DedupeMode = "exact"
+	// DedupeNormalized collapses runs of whitespace and trims the ends
+	// before comparing, so "ls  -la" and "ls -la" dedupe together.
+	DedupeNormalized DedupeMode = "normalized"
+	// DedupeFirstWord treats two commands as duplicates if they start
+	// with the same word, so every `git commit -m ...` collapses to one
+	// entry regardless of the message.
+	DedupeFirstWord DedupeMode = "first-word"
+)
+
+// RedactRule replaces matches of Pattern with Replacement (which may use
+// Go regexp replacement syntax, e.g. "$1") before a command reaches the
+// finder.
+type RedactRule struct {
+	Pattern     string `toml:"pattern"`
+	Replacement string `toml:"replacement"`
+}
+
+// Config holds historik's exclusion and redaction rules.
+type Config struct {
+	ExcludePatterns []string            `toml:"exclude_patterns"`
+	ExcludePrefixes []string            `toml:"exclude_prefixes"`
+	RedactPatterns  []RedactRule        `toml:"redact_patterns"`
+	MinLength       int                 `toml:"min_length"`
+	DedupeMode      DedupeMode          `toml:"dedupe_mode"`
+	CWDIgnore       map[string][]string `toml:"cwd_ignore"`
+
+	// RedactPatternsReplaceDefaults opts out of the default AWS-key/
+	// bearer-token/password/userinfo-URL redaction rules. By default,
+	// any redact_patterns set in a config file are added on top of
+	// those defaults rather than replacing them, since the defaults
+	// exist specifically to avoid leaking secrets into history and
+	// shouldn't silently disappear just because a user added one rule
+	// of their own. Set this to true to use exactly the patterns
+	// listed in redact_patterns instead (including none, if the list
+	// is left empty).
+	RedactPatternsReplaceDefaults bool `toml:"redact_patterns_replace_defaults"`
+
+	excludeRegexps []*regexp.Regexp
+	redactRegexps  []*regexp.Regexp
+	cwdIgnoreRegex map[string][]*regexp.Regexp
+}
+
+// FilePath returns the path historik reads its config from, honoring
+// XDG_CONFIG_HOME when set.
+func FilePath() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "historik", "config.toml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "historik", "config.toml"), nil
+}
+
+// Load reads and compiles the config file at path, applying Default's
+// values for anything unset. A missing file is not an error; it simply
+// yields Default().
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	// Decode into a fresh struct so that unset TOML fields don't zero
+	// out Default()'s values, then let anything present in the file
+	// override the default.
+	var parsed Config
+	if _, err := toml.Decode(string(data), &parsed); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	cfg.merge(&parsed)
+
+	if err := cfg.compile(); err != nil {
+		return nil, fmt.Errorf("compile config: %w", err)
+	}
+	return cfg, nil
+}
+
+// Default returns historik's built-in rules: excluding its own
+// invocations, and redacting the most common secret shapes that end up
+// in shell history (AWS access keys, bearer tokens, --password= flags,
+// and URLs with embedded userinfo).
+func Default() *Config {
+	cfg := &Config{
+		ExcludePrefixes: []string{"historik"},
+		DedupeMode:      DedupeExact,
+		RedactPatterns: []RedactRule{
+			{Pattern: `AKIA[0-9A-Z]{16}`, Replacement: "[REDACTED_AWS_KEY]"},
+			{Pattern: `(?i)(bearer\s+)[a-z0-9._-]+`, Replacement: "${1}[REDACTED_TOKEN]"},
+			{Pattern: `(--?password[= ])\S+`, Replacement: "${1}[REDACTED]"},
+			{Pattern: `(\w+://[^:/\s]+:)[^@/\s]+(@)`, Replacement: "${1}[REDACTED]${2}"},
+		},
+	}
+	if err := cfg.compile(); err != nil {
+		// The built-in patterns are tested and must always compile.
+		panic(fmt.Sprintf("config: invalid default pattern: %v", err))
+	}
+	return cfg
+}
+
+// merge overlays any fields parsed sets with non-zero values onto cfg.
+func (cfg *Config) merge(parsed *Config) {
+	if len(parsed.ExcludePatterns) > 0 {
+		cfg.ExcludePatterns = parsed.ExcludePatterns
+	}
+	if len(parsed.ExcludePrefixes) > 0 {
+		cfg.ExcludePrefixes = parsed.ExcludePrefixes
+	}
+	if parsed.RedactPatternsReplaceDefaults {
+		cfg.RedactPatterns = parsed.RedactPatterns
+	} else if len(parsed.RedactPatterns) > 0 {
+		cfg.RedactPatterns = append(cfg.RedactPatterns, parsed.RedactPatterns...)
+	}
+	if parsed.MinLength > 0 {
+		cfg.MinLength = parsed.MinLength
+	}
+	if parsed.DedupeMode != "" {
+		cfg.DedupeMode = parsed.DedupeMode
+	}
+	if len(parsed.CWDIgnore) > 0 {
+		cfg.CWDIgnore = parsed.CWDIgnore
+	}
+}
+
+// compile builds the regexps backing ExcludePatterns, RedactPatterns,
+// and CWDIgnore.
+func (cfg *Config) compile() error {
+	cfg.excludeRegexps = nil
+	for _, pattern := range cfg.ExcludePatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("exclude_patterns %q: %w", pattern, err)
+		}
+		cfg.excludeRegexps = append(cfg.excludeRegexps, re)
+	}
+
+	cfg.redactRegexps = nil
+	for _, rule := range cfg.RedactPatterns {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("redact_patterns %q: %w", rule.Pattern, err)
+		}
+		cfg.redactRegexps = append(cfg.redactRegexps, re)
+	}
+
+	if len(cfg.CWDIgnore) > 0 {
+		cfg.cwdIgnoreRegex = make(map[string][]*regexp.Regexp, len(cfg.CWDIgnore))
+		for cwd, patterns := range cfg.CWDIgnore {
+			for _, pattern := range patterns {
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return fmt.Errorf("cwd_ignore[%q] %q: %w", cwd, pattern, err)
+				}
+				cfg.cwdIgnoreRegex[cwd] = append(cfg.cwdIgnoreRegex[cwd], re)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ShouldExclude reports whether command (run in cwd) should be hidden
+// entirely, based on ExcludePrefixes, ExcludePatterns, MinLength, and any
+// CWDIgnore rules for cwd.
+func (cfg *Config) ShouldExclude(command, cwd string) bool {
+	if cfg.MinLength > 0 && len(command) < cfg.MinLength {
+		return true
+	}
+	for _, prefix := range cfg.ExcludePrefixes {
+		if strings.HasPrefix(command, prefix) {
+			return true
+		}
+	}
+	for _, re := range cfg.excludeRegexps {
+		if re.MatchString(command) {
+			return true
+		}
+	}
+	if cwd != "" {
+		for _, re := range cfg.cwdIgnoreRegex[cwd] {
+			if re.MatchString(command) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Redact applies every redact rule to command in order and returns the
+// result.
+func (cfg *Config) Redact(command string) string {
+	for i, re := range cfg.redactRegexps {
+		command = re.ReplaceAllString(command, cfg.RedactPatterns[i].Replacement)
+	}
+	return command
+}
+
+// DedupeKey returns the key two commands are compared by under the
+// configured DedupeMode.
+func (cfg *Config) DedupeKey(command string) string {
+	switch cfg.DedupeMode {
+	case DedupeNormalized:
+		return strings.Join(strings.Fields(command), " ")
+	case DedupeFirstWord:
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			return ""
+		}
+		return fields[0]
+	default:
+		return command
+	}
+}
@@ -0,0 +1,163 @@
+package history
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lemmyhemmingway/historik/store"
+)
+
+// Bash reads Bash's history file and executes commands via `bash -c`.
+//
+// Bash's history file is one command per line. If HISTTIMEFORMAT was set
+// when the entries were written, each command is preceded by a
+// "#<epoch>" comment line; otherwise entries have no timestamp.
+type Bash struct{}
+
+func (Bash) Name() string { return "bash" }
+
+// HistoryFile locates the Bash history file, checking HISTFILE first.
+func (Bash) HistoryFile() string {
+	if histfile := os.Getenv("HISTFILE"); histfile != "" {
+		if _, err := os.Stat(histfile); err == nil {
+			return histfile
+		}
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return firstNonEmptyFile(filepath.Join(homeDir, ".bash_history"))
+}
+
+// Parse reads the Bash history file into entries, picking up "#<epoch>"
+// timestamp comments when present.
+func (Bash) Parse(filename string) ([]store.HistoryEntry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []store.HistoryEntry
+	scanner := bufio.NewScanner(file)
+
+	var pendingTimestamp time.Time
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if ts, ok := parseBashTimestampComment(line); ok {
+			pendingTimestamp = ts
+			continue
+		}
+
+		command := strings.TrimSpace(line)
+		if command == "" {
+			pendingTimestamp = time.Time{}
+			continue
+		}
+
+		entries = append(entries, store.HistoryEntry{
+			Timestamp: pendingTimestamp,
+			Command:   command,
+		})
+		pendingTimestamp = time.Time{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// parseBashTimestampComment reports whether line is a "#<epoch>" comment
+// Bash writes ahead of each command when HISTTIMEFORMAT is set.
+func parseBashTimestampComment(line string) (time.Time, bool) {
+	if !strings.HasPrefix(line, "#") {
+		return time.Time{}, false
+	}
+	epoch, err := strconv.ParseInt(strings.TrimPrefix(line, "#"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(epoch, 0), true
+}
+
+// Stream scans the Bash history file backwards, emitting entries
+// newest-first as it goes, so a caller doesn't have to wait for the
+// whole file to be read before seeing the first result.
+func (Bash) Stream(filename string) (<-chan store.HistoryEntry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := newReverseLineReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	out := make(chan store.HistoryEntry)
+	go func() {
+		defer file.Close()
+		defer close(out)
+
+		// lookahead holds a line already read from reader but not yet
+		// consumed, for when a command turns out not to have a
+		// "#<epoch>" comment directly ahead of it (walking backward)
+		// and that line needs to be re-examined on its own.
+		var lookahead *string
+		nextLine := func() (string, error) {
+			if lookahead != nil {
+				line := *lookahead
+				lookahead = nil
+				return line, nil
+			}
+			return reader.ReadLine()
+		}
+
+		for {
+			line, err := nextLine()
+			if err != nil {
+				return
+			}
+
+			// An orphaned timestamp comment (no command directly
+			// ahead of it in the file) has nothing to attach to.
+			if _, ok := parseBashTimestampComment(line); ok {
+				continue
+			}
+
+			command := strings.TrimSpace(line)
+			if command == "" {
+				continue
+			}
+
+			var timestamp time.Time
+			if prev, err := nextLine(); err == nil {
+				if ts, ok := parseBashTimestampComment(prev); ok {
+					timestamp = ts
+				} else {
+					lookahead = &prev
+				}
+			}
+
+			out <- store.HistoryEntry{Timestamp: timestamp, Command: command}
+		}
+	}()
+
+	return out, nil
+}
+
+// Command executes command via `bash -c`.
+func (Bash) Command(command string) []string {
+	return []string{"bash", "-c", command}
+}
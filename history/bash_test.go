@@ -0,0 +1,97 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lemmyhemmingway/historik/store"
+)
+
+func TestBashParse(t *testing.T) {
+	// Excluding historik's own invocations is config.Config's job (see
+	// applyConfig in main.go), not Parse's, so a "historik ..." line
+	// comes through like any other command here.
+	path := writeTestFile(t, "#100\necho one\necho two\n#300\nhistorik --today\n")
+
+	entries, err := Bash{}.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if entries[0].Command != "echo one" || !entries[0].Timestamp.Equal(time.Unix(100, 0)) {
+		t.Errorf("entries[0] = %+v", entries[0])
+	}
+	if entries[1].Command != "echo two" || !entries[1].Timestamp.IsZero() {
+		t.Errorf("entries[1] = %+v, want zero timestamp (no preceding #epoch comment)", entries[1])
+	}
+	if entries[2].Command != "historik --today" || !entries[2].Timestamp.Equal(time.Unix(300, 0)) {
+		t.Errorf("entries[2] = %+v", entries[2])
+	}
+}
+
+func TestBashStreamIsNewestFirst(t *testing.T) {
+	path := writeTestFile(t, "echo one\necho two\necho three\n")
+
+	stream, err := Bash{}.Stream(path)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	var commands []string
+	for e := range stream {
+		commands = append(commands, e.Command)
+	}
+	want := []string{"echo three", "echo two", "echo one"}
+	for i := range want {
+		if commands[i] != want[i] {
+			t.Fatalf("commands = %v, want %v", commands, want)
+		}
+	}
+}
+
+func TestBashStreamMatchesParseReversed(t *testing.T) {
+	path := writeTestFile(t, "#100\necho one\necho two\n#300\necho three\n")
+
+	parsed, err := Bash{}.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	stream, err := Bash{}.Stream(path)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	var streamed []store.HistoryEntry
+	for e := range stream {
+		streamed = append(streamed, e)
+	}
+
+	if len(streamed) != len(parsed) {
+		t.Fatalf("streamed has %d entries, want %d", len(streamed), len(parsed))
+	}
+	for i := range parsed {
+		want := parsed[len(parsed)-1-i]
+		if streamed[i].Command != want.Command || !streamed[i].Timestamp.Equal(want.Timestamp) {
+			t.Errorf("streamed[%d] = %+v, want %+v", i, streamed[i], want)
+		}
+	}
+}
+
+func TestParseBashTimestampComment(t *testing.T) {
+	tests := []struct {
+		line   string
+		wantOK bool
+	}{
+		{"#1234567890", true},
+		{"#notanumber", false},
+		{"echo hi", false},
+		{"", false},
+	}
+	for _, tc := range tests {
+		_, ok := parseBashTimestampComment(tc.line)
+		if ok != tc.wantOK {
+			t.Errorf("parseBashTimestampComment(%q) ok = %v, want %v", tc.line, ok, tc.wantOK)
+		}
+	}
+}
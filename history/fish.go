@@ -0,0 +1,210 @@
+package history
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lemmyhemmingway/historik/store"
+)
+
+// Fish reads fish's fish_history file and executes commands via
+// `fish -c`.
+//
+// fish_history is a restricted, line-oriented subset of YAML: each entry
+// is a "- cmd: <command>" line followed by a "  when: <epoch>" line, and
+// optionally a "  paths:" block historik doesn't need. A command
+// containing newlines is written as a literal block scalar ("cmd: |-")
+// with each continuation line indented four spaces.
+type Fish struct{}
+
+func (Fish) Name() string { return "fish" }
+
+// HistoryFile locates fish's history file, honoring XDG_DATA_HOME.
+func (Fish) HistoryFile() string {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		if path := firstNonEmptyFile(filepath.Join(xdg, "fish", "fish_history")); path != "" {
+			return path
+		}
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return firstNonEmptyFile(filepath.Join(homeDir, ".local", "share", "fish", "fish_history"))
+}
+
+// Parse reads fish's fish_history file into entries.
+func (Fish) Parse(filename string) ([]store.HistoryEntry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []store.HistoryEntry
+	scanner := bufio.NewScanner(file)
+
+	var (
+		currentEntry  *store.HistoryEntry
+		inBlockScalar bool
+	)
+
+	flush := func() {
+		if currentEntry != nil && currentEntry.Command != "" {
+			currentEntry.Command = strings.TrimRight(currentEntry.Command, "\n")
+			entries = append(entries, *currentEntry)
+		}
+		currentEntry = nil
+		inBlockScalar = false
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "- cmd:"):
+			flush()
+			rest := strings.TrimSpace(strings.TrimPrefix(line, "- cmd:"))
+			if rest == "|-" || rest == "|" {
+				currentEntry = &store.HistoryEntry{}
+				inBlockScalar = true
+			} else {
+				currentEntry = &store.HistoryEntry{Command: unquoteFishScalar(rest)}
+			}
+
+		case inBlockScalar && strings.HasPrefix(line, "    "):
+			if currentEntry.Command != "" {
+				currentEntry.Command += "\n"
+			}
+			currentEntry.Command += strings.TrimPrefix(line, "    ")
+
+		case strings.HasPrefix(line, "  when:"):
+			inBlockScalar = false
+			epoch, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "  when:")), 10, 64)
+			if err == nil && currentEntry != nil {
+				currentEntry.Timestamp = time.Unix(epoch, 0)
+			}
+
+		default:
+			// Other fields (e.g. "  paths:" and its list items) aren't
+			// relevant to historik.
+			inBlockScalar = false
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// unquoteFishScalar strips the surrounding quotes fish adds around
+// commands that contain YAML-significant characters.
+func unquoteFishScalar(s string) string {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
+
+// Stream scans fish_history backwards, emitting entries newest-first as
+// it goes, so a caller doesn't have to wait for the whole file to be
+// read before seeing the first result. Each entry is self-delimited by
+// its leading "- cmd:" line, so this accumulates lines walking backward
+// until that marker is seen, then parses the accumulated group forward.
+func (Fish) Stream(filename string) (<-chan store.HistoryEntry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := newReverseLineReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	out := make(chan store.HistoryEntry)
+	go func() {
+		defer file.Close()
+		defer close(out)
+
+		// group accumulates an entry's lines in the order they're
+		// read, i.e. reverse of how they appear in the file.
+		var group []string
+
+		for {
+			line, err := reader.ReadLine()
+			if err != nil {
+				return
+			}
+
+			group = append(group, line)
+			if !strings.HasPrefix(line, "- cmd:") {
+				continue
+			}
+
+			if entry, ok := parseFishEntryGroup(group); ok {
+				out <- entry
+			}
+			group = nil
+		}
+	}()
+
+	return out, nil
+}
+
+// parseFishEntryGroup parses a single fish_history entry from linesReverse,
+// the entry's lines in reverse (last-line-first) order, as produced by
+// walking the file backward in Stream.
+func parseFishEntryGroup(linesReverse []string) (store.HistoryEntry, bool) {
+	var entry store.HistoryEntry
+	var inBlockScalar bool
+
+	for i := len(linesReverse) - 1; i >= 0; i-- {
+		line := linesReverse[i]
+
+		switch {
+		case strings.HasPrefix(line, "- cmd:"):
+			rest := strings.TrimSpace(strings.TrimPrefix(line, "- cmd:"))
+			if rest == "|-" || rest == "|" {
+				inBlockScalar = true
+			} else {
+				entry.Command = unquoteFishScalar(rest)
+			}
+
+		case inBlockScalar && strings.HasPrefix(line, "    "):
+			if entry.Command != "" {
+				entry.Command += "\n"
+			}
+			entry.Command += strings.TrimPrefix(line, "    ")
+
+		case strings.HasPrefix(line, "  when:"):
+			inBlockScalar = false
+			epoch, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "  when:")), 10, 64)
+			if err == nil {
+				entry.Timestamp = time.Unix(epoch, 0)
+			}
+
+		default:
+			inBlockScalar = false
+		}
+	}
+
+	if entry.Command == "" {
+		return store.HistoryEntry{}, false
+	}
+	return entry, true
+}
+
+// Command executes command via `fish -c`.
+func (Fish) Command(command string) []string {
+	return []string{"fish", "-c", command}
+}
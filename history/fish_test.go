@@ -0,0 +1,85 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lemmyhemmingway/historik/store"
+)
+
+func TestFishParse(t *testing.T) {
+	path := writeTestFile(t, "- cmd: echo one\n  when: 100\n- cmd: |-\n    echo two\n    echo more\n  when: 200\n- cmd: 'it''s quoted'\n  when: 300\n")
+
+	entries, err := Fish{}.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if entries[0].Command != "echo one" || !entries[0].Timestamp.Equal(time.Unix(100, 0)) {
+		t.Errorf("entries[0] = %+v", entries[0])
+	}
+	if entries[1].Command != "echo two\necho more" {
+		t.Errorf("entries[1].Command = %q, want block scalar reconstruction", entries[1].Command)
+	}
+	if entries[2].Command != "it's quoted" {
+		t.Errorf("entries[2].Command = %q, want unquoted", entries[2].Command)
+	}
+}
+
+func TestFishParseDoesNotFilterHistorik(t *testing.T) {
+	// Excluding historik's own invocations is config.Config's job (see
+	// applyConfig in main.go), not Parse's, so a "historik ..." entry
+	// comes through like any other command here.
+	path := writeTestFile(t, "- cmd: historik --today\n  when: 100\n- cmd: echo real\n  when: 200\n")
+
+	entries, err := Fish{}.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Command != "historik --today" || entries[1].Command != "echo real" {
+		t.Fatalf("entries = %+v, want both commands", entries)
+	}
+}
+
+func TestFishStreamMatchesParseReversed(t *testing.T) {
+	path := writeTestFile(t, "- cmd: echo one\n  when: 100\n- cmd: |-\n    echo two\n    echo more\n  when: 200\n- cmd: 'it''s quoted'\n  when: 300\n")
+
+	parsed, err := Fish{}.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	stream, err := Fish{}.Stream(path)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	var streamed []store.HistoryEntry
+	for e := range stream {
+		streamed = append(streamed, e)
+	}
+
+	if len(streamed) != len(parsed) {
+		t.Fatalf("streamed has %d entries, want %d", len(streamed), len(parsed))
+	}
+	for i := range parsed {
+		want := parsed[len(parsed)-1-i]
+		if streamed[i].Command != want.Command || !streamed[i].Timestamp.Equal(want.Timestamp) {
+			t.Errorf("streamed[%d] = %+v, want %+v", i, streamed[i], want)
+		}
+	}
+}
+
+func TestUnquoteFishScalar(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"echo hi", "echo hi"},
+		{"'quoted'", "quoted"},
+		{"'it''s quoted'", "it's quoted"},
+	}
+	for _, tc := range tests {
+		if got := unquoteFishScalar(tc.in); got != tc.want {
+			t.Errorf("unquoteFishScalar(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
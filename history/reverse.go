@@ -0,0 +1,77 @@
+package history
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// reverseChunkSize is how much of the file reverseLineReader reads per
+// disk access while walking backward from the end.
+const reverseChunkSize = 64 * 1024
+
+// reverseLineReader yields the lines of a file from last to first
+// without loading the whole file into memory, so a caller can start
+// producing results before the read reaches the front of a multi-MB
+// history file.
+type reverseLineReader struct {
+	file *os.File
+	pos  int64
+	buf  []byte
+}
+
+func newReverseLineReader(file *os.File) (*reverseLineReader, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// A well-formed text file ends with a trailing newline; without
+	// this adjustment that byte would surface as a spurious empty
+	// "line" ahead of the real last line. bufio.Scanner has the same
+	// convention of not emitting an empty final token in this case.
+	size := info.Size()
+	if size > 0 {
+		var last [1]byte
+		if _, err := file.ReadAt(last[:], size-1); err != nil {
+			return nil, err
+		}
+		if last[0] == '\n' {
+			size--
+		}
+	}
+
+	return &reverseLineReader{file: file, pos: size}, nil
+}
+
+// ReadLine returns the next line walking backward from the end of the
+// file, or io.EOF once the start of the file has been reached.
+func (r *reverseLineReader) ReadLine() (string, error) {
+	for {
+		if idx := bytes.LastIndexByte(r.buf, '\n'); idx != -1 {
+			line := string(r.buf[idx+1:])
+			r.buf = r.buf[:idx]
+			return line, nil
+		}
+		if r.pos == 0 {
+			if len(r.buf) == 0 {
+				return "", io.EOF
+			}
+			line := string(r.buf)
+			r.buf = nil
+			return line, nil
+		}
+
+		readSize := int64(reverseChunkSize)
+		if readSize > r.pos {
+			readSize = r.pos
+		}
+		r.pos -= readSize
+
+		chunk := make([]byte, readSize)
+		if _, err := r.file.ReadAt(chunk, r.pos); err != nil {
+			return "", err
+		}
+		r.buf = append(chunk, r.buf...)
+	}
+}
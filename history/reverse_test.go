@@ -0,0 +1,96 @@
+package history
+
+import (
+	"io"
+	"os"
+	"testing"
+)
+
+func readAllReverse(t *testing.T, contents string) []string {
+	t.Helper()
+	path := writeTestFile(t, contents)
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer file.Close()
+
+	reader, err := newReverseLineReader(file)
+	if err != nil {
+		t.Fatalf("newReverseLineReader: %v", err)
+	}
+
+	var lines []string
+	for {
+		line, err := reader.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadLine: %v", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestReverseLineReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{
+			name:     "trailing newline",
+			contents: "one\ntwo\nthree\n",
+			want:     []string{"three", "two", "one"},
+		},
+		{
+			name:     "no trailing newline",
+			contents: "one\ntwo\nthree",
+			want:     []string{"three", "two", "one"},
+		},
+		{
+			name:     "empty file",
+			contents: "",
+			want:     nil,
+		},
+		{
+			name:     "single line no newline",
+			contents: "only",
+			want:     []string{"only"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := readAllReverse(t, tc.contents)
+			if len(got) != len(tc.want) {
+				t.Fatalf("lines = %v, want %v", got, tc.want)
+			}
+			for i := range tc.want {
+				if got[i] != tc.want[i] {
+					t.Errorf("lines[%d] = %q, want %q", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReverseLineReaderAcrossChunkBoundary(t *testing.T) {
+	// Build a file bigger than reverseChunkSize so ReadLine has to stitch
+	// a line across two ReadAt calls.
+	var contents string
+	for i := 0; i < reverseChunkSize/8; i++ {
+		contents += "0123456\n"
+	}
+	contents += "final line\n"
+
+	got := readAllReverse(t, contents)
+	if len(got) == 0 || got[0] != "final line" {
+		t.Fatalf("first (newest) line = %q, want %q", got[0], "final line")
+	}
+	if len(got) != reverseChunkSize/8+1 {
+		t.Fatalf("got %d lines, want %d", len(got), reverseChunkSize/8+1)
+	}
+}
@@ -0,0 +1,95 @@
+// Package history abstracts over the history file format and command
+// execution conventions of the shells historik supports, so the rest of
+// the tool doesn't need to hard-code Zsh.
+package history
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lemmyhemmingway/historik/store"
+)
+
+// Source locates, parses, and executes commands for a single shell's
+// history file.
+type Source interface {
+	// Name identifies the shell this source supports, e.g. "zsh".
+	Name() string
+
+	// HistoryFile locates this shell's history file, honoring whatever
+	// environment variable that shell uses to override the default
+	// location. It returns "" if no history file can be found.
+	HistoryFile() string
+
+	// Parse reads history entries out of the file at path.
+	Parse(path string) ([]store.HistoryEntry, error)
+
+	// Stream behaves like Parse, but emits entries newest-first on a
+	// channel as they're read instead of returning only once the whole
+	// file has been scanned, so a consumer piping into fzf can start
+	// showing results within milliseconds regardless of history size.
+	// The channel is closed once the file has been fully read or an
+	// error occurs.
+	Stream(path string) (<-chan store.HistoryEntry, error)
+
+	// Command returns the argv used to execute command under this shell,
+	// e.g. []string{"zsh", "-c", command}.
+	Command(command string) []string
+}
+
+// Detect returns the Source for shell, which must be "zsh", "bash",
+// "fish", or "auto". "auto" picks a Source based on the $SHELL
+// environment variable, falling back to zsh if it's unset or
+// unrecognized.
+func Detect(shell string) (Source, error) {
+	if shell == "" || shell == "auto" {
+		shell = shellFromEnv()
+	}
+
+	switch shell {
+	case "zsh":
+		return Zsh{}, nil
+	case "bash":
+		return Bash{}, nil
+	case "fish":
+		return Fish{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported shell %q (want zsh, bash, or fish)", shell)
+	}
+}
+
+// shellFromEnv guesses the user's shell from $SHELL, defaulting to zsh.
+func shellFromEnv() string {
+	switch base := baseName(os.Getenv("SHELL")); base {
+	case "bash":
+		return "bash"
+	case "fish":
+		return "fish"
+	default:
+		return "zsh"
+	}
+}
+
+// baseName returns the last path element of p without using path/filepath,
+// since shell paths here are always slash-separated regardless of host OS.
+func baseName(p string) string {
+	for i := len(p) - 1; i >= 0; i-- {
+		if p[i] == '/' {
+			return p[i+1:]
+		}
+	}
+	return p
+}
+
+// firstNonEmptyFile returns the first existing, statable path in candidates.
+func firstNonEmptyFile(candidates ...string) string {
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package history
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		shell   string
+		want    string
+		wantErr bool
+	}{
+		{shell: "zsh", want: "zsh"},
+		{shell: "bash", want: "bash"},
+		{shell: "fish", want: "fish"},
+		{shell: "tcsh", wantErr: true},
+	}
+	for _, tc := range tests {
+		src, err := Detect(tc.shell)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("Detect(%q) error = nil, want error", tc.shell)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Detect(%q): %v", tc.shell, err)
+		}
+		if src.Name() != tc.want {
+			t.Errorf("Detect(%q).Name() = %q, want %q", tc.shell, src.Name(), tc.want)
+		}
+	}
+}
+
+func TestShellFromEnv(t *testing.T) {
+	tests := []struct {
+		shellEnv string
+		want     string
+	}{
+		{"/bin/bash", "bash"},
+		{"/usr/local/bin/fish", "fish"},
+		{"/usr/bin/zsh", "zsh"},
+		{"", "zsh"},
+		{"/bin/tcsh", "zsh"},
+	}
+	for _, tc := range tests {
+		t.Setenv("SHELL", tc.shellEnv)
+		if got := shellFromEnv(); got != tc.want {
+			t.Errorf("shellFromEnv() with SHELL=%q = %q, want %q", tc.shellEnv, got, tc.want)
+		}
+	}
+}
+
+func TestBaseName(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"/usr/bin/zsh", "zsh"},
+		{"zsh", "zsh"},
+		{"", ""},
+		{"/", ""},
+	}
+	for _, tc := range tests {
+		if got := baseName(tc.in); got != tc.want {
+			t.Errorf("baseName(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package history
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lemmyhemmingway/historik/store"
+)
+
+// Zsh reads Zsh's extended history format and executes commands via
+// `zsh -c`.
+type Zsh struct{}
+
+// extendedHistoryRegex matches the Zsh extended history format:
+// ": timestamp:elapsed;command"
+var extendedHistoryRegex = regexp.MustCompile(`^: (\d+):\d+;(.*)$`)
+
+func (Zsh) Name() string { return "zsh" }
+
+// HistoryFile locates the Zsh history file, checking HISTFILE first.
+func (Zsh) HistoryFile() string {
+	if histfile := os.Getenv("HISTFILE"); histfile != "" {
+		if _, err := os.Stat(histfile); err == nil {
+			return histfile
+		}
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return firstNonEmptyFile(filepath.Join(homeDir, ".zsh_history"))
+}
+
+// Parse parses the Zsh history file, which can contain multi-line commands.
+func (Zsh) Parse(filename string) ([]store.HistoryEntry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []store.HistoryEntry
+	scanner := bufio.NewScanner(file)
+
+	var currentEntry *store.HistoryEntry
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Attempt to match a new history entry with a timestamp.
+		if matches := extendedHistoryRegex.FindStringSubmatch(line); matches != nil {
+			// If a new entry is found, and there's a previous entry to save, save it.
+			if currentEntry != nil {
+				if currentEntry.Command != "" {
+					entries = append(entries, *currentEntry)
+				}
+			}
+
+			// Start a new history entry.
+			timestamp, err := strconv.ParseInt(matches[1], 10, 64)
+			var entryTimestamp time.Time
+			if err == nil {
+				entryTimestamp = time.Unix(timestamp, 0)
+			}
+			currentEntry = &store.HistoryEntry{
+				Timestamp: entryTimestamp,
+				Command:   strings.TrimSpace(matches[2]),
+			}
+		} else if currentEntry != nil {
+			// This line is a continuation of the previous command.
+			currentEntry.Command += "\n" + line
+		}
+	}
+
+	// Process the final entry after the loop finishes.
+	if currentEntry != nil {
+		if currentEntry.Command != "" {
+			entries = append(entries, *currentEntry)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Stream scans the Zsh history file backwards, emitting entries
+// newest-first as it goes, so a caller doesn't have to wait for the
+// whole file to be read (and then sorted) before seeing the first
+// result.
+func (Zsh) Stream(filename string) (<-chan store.HistoryEntry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := newReverseLineReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	out := make(chan store.HistoryEntry)
+	go func() {
+		defer file.Close()
+		defer close(out)
+
+		// Continuation lines of a multi-line command are read before
+		// the ": timestamp:elapsed;..." line that starts it, since
+		// we're walking the file backward; stash them here until that
+		// line is found, then emit the reassembled entry.
+		var pendingLines []string
+
+		for {
+			line, err := reader.ReadLine()
+			if err != nil {
+				return
+			}
+
+			matches := extendedHistoryRegex.FindStringSubmatch(line)
+			if matches == nil {
+				pendingLines = append([]string{line}, pendingLines...)
+				continue
+			}
+
+			command := strings.TrimSpace(matches[2])
+			if len(pendingLines) > 0 {
+				command += "\n" + strings.Join(pendingLines, "\n")
+			}
+			pendingLines = nil
+
+			if command == "" {
+				continue
+			}
+
+			var timestamp time.Time
+			if unixTime, err := strconv.ParseInt(matches[1], 10, 64); err == nil {
+				timestamp = time.Unix(unixTime, 0)
+			}
+
+			out <- store.HistoryEntry{Timestamp: timestamp, Command: command}
+		}
+	}()
+
+	return out, nil
+}
+
+// Command executes command via `zsh -c`, which respects shell features
+// like aliases.
+func (Zsh) Command(command string) []string {
+	return []string{"zsh", "-c", command}
+}
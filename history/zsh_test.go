@@ -0,0 +1,85 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "histfile")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestZshParse(t *testing.T) {
+	// Excluding historik's own invocations is config.Config's job (see
+	// applyConfig in main.go), not Parse's, so a "historik ..." line
+	// comes through like any other command here.
+	path := writeTestFile(t, ": 100:0;echo one\n: 200:0;echo two\nmore two\n: 300:0;historik --today\n")
+
+	entries, err := Zsh{}.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if entries[0].Command != "echo one" || !entries[0].Timestamp.Equal(time.Unix(100, 0)) {
+		t.Errorf("entries[0] = %+v", entries[0])
+	}
+	if entries[1].Command != "echo two\nmore two" {
+		t.Errorf("entries[1].Command = %q, want multi-line reconstruction", entries[1].Command)
+	}
+	if entries[2].Command != "historik --today" {
+		t.Errorf("entries[2].Command = %q, want %q", entries[2].Command, "historik --today")
+	}
+}
+
+func TestZshStreamMatchesParseReversed(t *testing.T) {
+	path := writeTestFile(t, ": 100:0;echo one\n: 200:0;echo two\nmore two\n: 300:0;echo three\n")
+
+	parsed, err := Zsh{}.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	stream, err := Zsh{}.Stream(path)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	var streamed []string
+	for e := range stream {
+		streamed = append(streamed, e.Command)
+	}
+
+	want := []string{"echo three", "echo two\nmore two", "echo one"}
+	if len(streamed) != len(want) {
+		t.Fatalf("streamed = %v, want %v", streamed, want)
+	}
+	for i := range want {
+		if streamed[i] != want[i] {
+			t.Errorf("streamed[%d] = %q, want %q", i, streamed[i], want[i])
+		}
+	}
+	if len(parsed) != len(want) {
+		t.Fatalf("parsed has %d entries, want %d", len(parsed), len(want))
+	}
+}
+
+func TestZshCommand(t *testing.T) {
+	got := Zsh{}.Command("echo hi")
+	want := []string{"zsh", "-c", "echo hi"}
+	if len(got) != len(want) {
+		t.Fatalf("Command() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Command() = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Package editline lets the user review and tweak a selected history
+// entry before it runs, readline-style, instead of executing it the
+// instant it's chosen.
+package editline
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Editor is a minimal raw-mode line editor pre-populated with a chosen
+// command. It keeps its own persistent history of edited commands,
+// separate from the shell's own history file.
+type Editor struct {
+	historyPath string
+	history     []string
+}
+
+// New returns an Editor whose history is loaded from
+// $XDG_CACHE_HOME/historik/edit_history (or ~/.cache/historik/edit_history).
+func New() (*Editor, error) {
+	path, err := historyFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("locate edit history: %w", err)
+	}
+
+	e := &Editor{historyPath: path}
+	e.history, err = loadHistory(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("load edit history: %w", err)
+	}
+	return e, nil
+}
+
+// Close persists the edit history back to disk.
+func (e *Editor) Close() error {
+	if e.historyPath == "" {
+		return nil
+	}
+	return saveHistory(e.historyPath, e.history)
+}
+
+// Edit lets the user review and modify initial before running it. It
+// returns the final command and true, or ok=false if the user aborted
+// with Ctrl-C.
+func (e *Editor) Edit(initial string) (command string, ok bool, err error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		// Nothing to edit interactively; run the command as selected.
+		return initial, true, nil
+	}
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", false, fmt.Errorf("enter raw terminal mode: %v", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	reader := bufio.NewReader(os.Stdin)
+	buf := []rune(initial)
+	cursor := len(buf)
+	nav := newHistoryNav(e.history, initial)
+
+	redraw := func() {
+		fmt.Print("\r\033[K")
+		fmt.Printf("$ %s", string(buf))
+		if back := len(buf) - cursor; back > 0 {
+			fmt.Printf("\033[%dD", back)
+		}
+	}
+	redraw()
+
+	for {
+		key, err := readKey(reader)
+		if err != nil {
+			return "", false, fmt.Errorf("read input: %v", err)
+		}
+
+		switch key {
+		case keyEnter:
+			fmt.Print("\r\n")
+			final := string(buf)
+			e.remember(final)
+			return final, true, nil
+
+		case keyCtrlC:
+			fmt.Print("\r\n")
+			return "", false, nil
+
+		case keyBackspace:
+			if cursor > 0 {
+				buf = append(buf[:cursor-1], buf[cursor:]...)
+				cursor--
+			}
+
+		case keyLeft:
+			if cursor > 0 {
+				cursor--
+			}
+
+		case keyRight:
+			if cursor < len(buf) {
+				cursor++
+			}
+
+		case keyUp:
+			if s, ok := nav.up(string(buf)); ok {
+				buf = []rune(s)
+				cursor = len(buf)
+			}
+
+		case keyDown:
+			if s, ok := nav.down(); ok {
+				buf = []rune(s)
+				cursor = len(buf)
+			}
+
+		case keyAltE:
+			edited, editErr := openInEditor(fd, oldState, string(buf))
+			if editErr != nil {
+				fmt.Printf("\r\n\033[31mError: %v\033[0m\r\n", editErr)
+				redraw()
+				continue
+			}
+			buf = []rune(strings.TrimRight(edited, "\n"))
+			cursor = len(buf)
+
+		default:
+			if key >= 0x20 && key < 0x7f {
+				buf = append(buf[:cursor], append([]rune{rune(key)}, buf[cursor:]...)...)
+				cursor++
+			}
+		}
+
+		redraw()
+	}
+}
+
+// historyNav walks Editor.history with the usual shell readline
+// convention: Up/Down move through past entries, and the in-progress
+// line being edited is stashed the moment the user steps away from it so
+// Down can restore it once they return to the end of history.
+type historyNav struct {
+	entries []string
+	idx     int
+	saved   string
+}
+
+func newHistoryNav(entries []string, initial string) *historyNav {
+	return &historyNav{entries: entries, idx: len(entries), saved: initial}
+}
+
+// up returns the previous history entry, given current is the line the
+// user is editing right now. ok is false at the start of history.
+func (h *historyNav) up(current string) (line string, ok bool) {
+	if h.idx <= 0 {
+		return "", false
+	}
+	if h.idx == len(h.entries) {
+		h.saved = current
+	}
+	h.idx--
+	return h.entries[h.idx], true
+}
+
+// down returns the next history entry, or the stashed in-progress line
+// once it steps past the end of history. ok is false if already there.
+func (h *historyNav) down() (line string, ok bool) {
+	if h.idx >= len(h.entries) {
+		return "", false
+	}
+	h.idx++
+	if h.idx == len(h.entries) {
+		return h.saved, true
+	}
+	return h.entries[h.idx], true
+}
+
+// remember appends command to the in-memory history, skipping empty or
+// back-to-back duplicate entries.
+func (e *Editor) remember(command string) {
+	if command == "" {
+		return
+	}
+	if len(e.history) > 0 && e.history[len(e.history)-1] == command {
+		return
+	}
+	e.history = append(e.history, command)
+}
+
+// historyFilePath returns the path to historik's edit history file,
+// honoring XDG_CACHE_HOME when set.
+func historyFilePath() (string, error) {
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "historik", "edit_history"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cache", "historik", "edit_history"), nil
+}
@@ -0,0 +1,85 @@
+package editline
+
+import "testing"
+
+func TestHistoryNavUpDown(t *testing.T) {
+	nav := newHistoryNav([]string{"one", "two", "three"}, "in progress")
+
+	line, ok := nav.up("in progress")
+	if !ok || line != "three" {
+		t.Fatalf("first up = (%q, %v), want (three, true)", line, ok)
+	}
+
+	line, ok = nav.up("three")
+	if !ok || line != "two" {
+		t.Fatalf("second up = (%q, %v), want (two, true)", line, ok)
+	}
+
+	line, ok = nav.up("two")
+	if !ok || line != "one" {
+		t.Fatalf("third up = (%q, %v), want (one, true)", line, ok)
+	}
+
+	if _, ok := nav.up("one"); ok {
+		t.Fatal("up at the start of history should return ok=false")
+	}
+
+	line, ok = nav.down()
+	if !ok || line != "two" {
+		t.Fatalf("first down = (%q, %v), want (two, true)", line, ok)
+	}
+
+	line, ok = nav.down()
+	if !ok || line != "three" {
+		t.Fatalf("second down = (%q, %v), want (three, true)", line, ok)
+	}
+
+	line, ok = nav.down()
+	if !ok || line != "in progress" {
+		t.Fatalf("down past the end should restore the stashed line, got (%q, %v)", line, ok)
+	}
+
+	if _, ok := nav.down(); ok {
+		t.Fatal("down at the end of history should return ok=false")
+	}
+}
+
+func TestHistoryNavStashesEditsMidNavigation(t *testing.T) {
+	nav := newHistoryNav([]string{"one", "two"}, "original")
+
+	if _, ok := nav.up("original"); !ok {
+		t.Fatal("up should succeed")
+	}
+	// The user edits the recalled entry before navigating away from it.
+	line, ok := nav.down()
+	if !ok || line != "original" {
+		t.Fatalf("down should restore the original in-progress line, got (%q, %v)", line, ok)
+	}
+}
+
+func TestEditorRememberSkipsEmptyAndAdjacentDuplicates(t *testing.T) {
+	e := &Editor{}
+
+	e.remember("")
+	if len(e.history) != 0 {
+		t.Fatalf("remember(\"\") should be a no-op, history = %v", e.history)
+	}
+
+	e.remember("echo one")
+	e.remember("echo one")
+	if len(e.history) != 1 {
+		t.Fatalf("back-to-back duplicate should be skipped, history = %v", e.history)
+	}
+
+	e.remember("echo two")
+	e.remember("echo one")
+	want := []string{"echo one", "echo two", "echo one"}
+	if len(e.history) != len(want) {
+		t.Fatalf("history = %v, want %v", e.history, want)
+	}
+	for i := range want {
+		if e.history[i] != want[i] {
+			t.Errorf("history[%d] = %q, want %q", i, e.history[i], want[i])
+		}
+	}
+}
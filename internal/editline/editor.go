@@ -0,0 +1,52 @@
+package editline
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"golang.org/x/term"
+)
+
+// openInEditor writes buffer to a temp file, opens it in $EDITOR (falling
+// back to vi), and returns the file's contents afterwards. The terminal
+// is restored to cooked mode (oldState) for the duration so the editor
+// can manage it itself, then put back into raw mode before returning.
+func openInEditor(fd int, oldState *term.State, buffer string) (string, error) {
+	file, err := os.CreateTemp("", "historik-edit-*.sh")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+	path := file.Name()
+	defer os.Remove(path)
+
+	if _, err := file.WriteString(buffer); err != nil {
+		file.Close()
+		return "", fmt.Errorf("write temp file: %w", err)
+	}
+	file.Close()
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+
+	if err := term.Restore(fd, oldState); err != nil {
+		return "", fmt.Errorf("restore terminal mode: %w", err)
+	}
+	defer term.MakeRaw(fd)
+
+	cmd := exec.Command(editor, path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("run %s: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read temp file: %w", err)
+	}
+	return string(edited), nil
+}
@@ -0,0 +1,103 @@
+package editline
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// loadHistory reads the edit history file, one entry per line. Commands
+// that span multiple lines are stored with their newlines escaped as
+// "\n" so the file stays line-oriented.
+func loadHistory(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var history []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		history = append(history, unescapeNewlines(scanner.Text()))
+	}
+	return history, scanner.Err()
+}
+
+// saveHistory writes history back to path, one escaped entry per line.
+func saveHistory(path string, history []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, command := range history {
+		if _, err := writer.WriteString(escapeNewlines(command) + "\n"); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// escapeNewlines backslash-escapes newlines and backslashes so a
+// multi-line command can round-trip through the line-oriented history
+// file. Escaping backslashes too (not just newlines) is what lets
+// unescapeNewlines tell an escaped newline apart from a command that
+// already contains the literal two-character sequence `\n`, e.g.
+// `printf 'a\nb'`: that text's backslash gets doubled here, so on the
+// way back out it decodes to itself instead of being mistaken for an
+// escape.
+func escapeNewlines(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// unescapeNewlines reverses escapeNewlines. It walks the string rune by
+// rune rather than chaining strings.ReplaceAll calls, since a naive
+// replace-in-sequence can't distinguish an escape sequence from literal
+// text that happens to already contain it.
+func unescapeNewlines(s string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if escaped {
+			switch r {
+			case '\\':
+				b.WriteRune('\\')
+			case 'n':
+				b.WriteRune('\n')
+			default:
+				b.WriteRune('\\')
+				b.WriteRune(r)
+			}
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		b.WriteRune(r)
+	}
+	if escaped {
+		b.WriteRune('\\')
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package editline
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestEscapeNewlinesRoundTrip(t *testing.T) {
+	tests := []string{
+		"echo hi",
+		"printf 'a\nb'",
+		`printf 'a\nb'`, // literal backslash-n, not an actual newline
+		`echo a\b`,
+		"trailing backslash\\",
+		"multi\nline\ncommand",
+		"",
+	}
+	for _, in := range tests {
+		escaped := escapeNewlines(in)
+		if containsNewline(escaped) {
+			t.Errorf("escapeNewlines(%q) = %q still contains a raw newline", in, escaped)
+		}
+		if got := unescapeNewlines(escaped); got != in {
+			t.Errorf("unescapeNewlines(escapeNewlines(%q)) = %q, want %q", in, got, in)
+		}
+	}
+}
+
+func containsNewline(s string) bool {
+	for _, r := range s {
+		if r == '\n' {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSaveAndLoadHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "edit_history")
+	want := []string{"echo one", "printf 'a\nb'", `echo literal\nbackslash-n`}
+
+	if err := saveHistory(path, want); err != nil {
+		t.Fatalf("saveHistory: %v", err)
+	}
+
+	got, err := loadHistory(path)
+	if err != nil {
+		t.Fatalf("loadHistory: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("loaded %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
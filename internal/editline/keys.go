@@ -0,0 +1,68 @@
+package editline
+
+import "bufio"
+
+// Key codes returned by readKey. Printable runes are returned as-is.
+const (
+	keyEnter     = '\r'
+	keyBackspace = 0x7f
+	keyCtrlC     = 0x03
+	keyEscape    = -1
+	keyUp        = -2
+	keyDown      = -3
+	keyLeft      = -4
+	keyRight     = -5
+	keyAltE      = -6
+)
+
+// readKey reads a single keypress from r, translating the ANSI escape
+// sequences for the arrow keys, the Alt-E chord, and a bare Escape into
+// the key* constants.
+func readKey(r *bufio.Reader) (int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	if b == '\n' {
+		return keyEnter, nil
+	}
+	if b != 0x1b {
+		return int(b), nil
+	}
+
+	// Possibly an escape sequence; if nothing follows immediately,
+	// treat it as a bare Escape.
+	if r.Buffered() == 0 {
+		return keyEscape, nil
+	}
+
+	second, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	if second == 'e' {
+		return keyAltE, nil
+	}
+	if second != '[' {
+		return keyEscape, nil
+	}
+
+	third, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch third {
+	case 'A':
+		return keyUp, nil
+	case 'B':
+		return keyDown, nil
+	case 'C':
+		return keyRight, nil
+	case 'D':
+		return keyLeft, nil
+	default:
+		return keyEscape, nil
+	}
+}
@@ -0,0 +1,193 @@
+package finder
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sahilm/fuzzy"
+	"golang.org/x/term"
+
+	"github.com/lemmyhemmingway/historik/store"
+)
+
+// maxResults bounds how many matches are rendered at once, mirroring
+// fzf's default windowed view rather than dumping the whole history.
+const maxResults = 10
+
+// sortMode selects how Embedded orders matches.
+type sortMode int
+
+const (
+	sortRecency sortMode = iota
+	sortScore
+)
+
+// Embedded is a self-contained fuzzy finder used when fzf isn't
+// available. It supports incremental filtering as the user types, a
+// reverse layout (prompt on top, results below), Ctrl-R to cycle between
+// recency and score ordering, and a preview pane for the highlighted
+// entry.
+type Embedded struct{}
+
+// Select renders an interactive fuzzy finder over entries (emitted
+// newest-first) and returns the chosen command, or "" if the user
+// aborted. Unlike ExternalFZF, the embedded finder needs random access
+// for filtering and sorting, so it drains the channel up front rather
+// than acting on entries as they stream in.
+func (Embedded) Select(entryChan <-chan store.HistoryEntry) (string, error) {
+	var entries []store.HistoryEntry
+	for entry := range entryChan {
+		entries = append(entries, entry)
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("builtin finder requires an interactive terminal")
+	}
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", fmt.Errorf("enter raw terminal mode: %v", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	commands := make([]string, len(entries))
+	for i, e := range entries {
+		commands[i] = e.Command
+	}
+
+	var (
+		query    []rune
+		mode     = sortRecency
+		selected int
+	)
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		matches := filterAndSort(commands, string(query), mode)
+		if selected >= len(matches) {
+			selected = len(matches) - 1
+		}
+		if selected < 0 {
+			selected = 0
+		}
+
+		render(string(query), mode, entries, matches, selected)
+
+		key, err := readKey(reader)
+		if err != nil {
+			return "", fmt.Errorf("read input: %v", err)
+		}
+
+		switch key {
+		case keyEnter:
+			if len(matches) == 0 {
+				continue
+			}
+			fmt.Print("\r\n")
+			return entries[matches[selected]].Command, nil
+		case keyEscape, keyCtrlC:
+			fmt.Print("\r\n")
+			return "", nil
+		case keyCtrlR:
+			if mode == sortRecency {
+				mode = sortScore
+			} else {
+				mode = sortRecency
+			}
+			selected = 0
+		case keyUp:
+			if selected > 0 {
+				selected--
+			}
+		case keyDown:
+			if selected < len(matches)-1 {
+				selected++
+			}
+		case keyBackspace:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				selected = 0
+			}
+		default:
+			if key >= 0x20 && key < 0x7f {
+				query = append(query, rune(key))
+				selected = 0
+			}
+		}
+	}
+}
+
+// filterAndSort returns the indexes into commands that match query,
+// ordered according to mode. An empty query matches everything.
+func filterAndSort(commands []string, query string, mode sortMode) []int {
+	if query == "" {
+		all := make([]int, len(commands))
+		for i := range commands {
+			all[i] = i
+		}
+		return all
+	}
+
+	matches := fuzzy.Find(query, commands)
+	indexes := make([]int, len(matches))
+	for i, m := range matches {
+		indexes[i] = m.Index
+	}
+
+	// fuzzy.Find already returns matches in descending score order; for
+	// recency mode, re-sort the matching set back into original
+	// (newest-first) order instead.
+	if mode == sortRecency {
+		sort.Ints(indexes)
+	}
+	return indexes
+}
+
+// render redraws the prompt, result list, and preview pane in place.
+func render(query string, mode sortMode, entries []store.HistoryEntry, matches []int, selected int) {
+	fmt.Print("\033[2J\033[H")
+
+	modeName := "recency"
+	if mode == sortScore {
+		modeName = "score"
+	}
+	fmt.Printf("Historik > %s\r\n", query)
+	fmt.Printf("\033[2m%d/%d  ctrl-r: sort (%s)  enter: run  esc: quit\033[0m\r\n", len(matches), len(entries), modeName)
+
+	shown := matches
+	if len(shown) > maxResults {
+		shown = shown[:maxResults]
+	}
+	for i, idx := range shown {
+		line := entries[idx].Command
+		if nl := strings.IndexByte(line, '\n'); nl != -1 {
+			line = line[:nl] + " …"
+		}
+		if i == selected {
+			fmt.Printf("\033[7m> %s\033[0m\r\n", line)
+		} else {
+			fmt.Printf("  %s\r\n", line)
+		}
+	}
+
+	fmt.Print("\033[2m---\033[0m\r\n")
+	if len(matches) > 0 {
+		entry := entries[matches[selected]]
+		status := fmt.Sprintf("exit: %d", entry.ExitCode)
+		if entry.ExitCode != 0 {
+			status = "\033[31m" + status + "\033[0m"
+		}
+		fmt.Printf("%s\r\n", status)
+		if entry.CWD != "" {
+			fmt.Printf("cwd:  %s\r\n", entry.CWD)
+		}
+		if !entry.Timestamp.IsZero() {
+			fmt.Printf("time: %s\r\n", entry.Timestamp.Format(time.RFC1123))
+		}
+	}
+}
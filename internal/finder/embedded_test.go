@@ -0,0 +1,62 @@
+package finder
+
+import (
+	"testing"
+)
+
+func TestFilterAndSortEmptyQueryReturnsAllInOrder(t *testing.T) {
+	commands := []string{"echo one", "echo two", "echo three"}
+
+	got := filterAndSort(commands, "", sortRecency)
+
+	want := []int{0, 1, 2}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFilterAndSortRecencyKeepsOriginalOrder(t *testing.T) {
+	// All three fuzzy-match "git", but sortRecency should restore
+	// newest-first (original index) order rather than score order.
+	commands := []string{"git status", "ls", "git commit", "git push"}
+
+	got := filterAndSort(commands, "git", sortRecency)
+
+	want := []int{0, 2, 3}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFilterAndSortScoreRanksBetterMatchesFirst(t *testing.T) {
+	// "git commit" should score higher against "gco" than "ls" or an
+	// unrelated git subcommand, so sortScore should surface it even
+	// though it's not the newest entry.
+	commands := []string{"git commit", "ls", "git checkout origin"}
+
+	got := filterAndSort(commands, "gco", sortScore)
+
+	if len(got) == 0 || got[0] != 2 {
+		t.Fatalf("got %v, want index 2 (\"git checkout origin\") ranked first", got)
+	}
+}
+
+func TestFilterAndSortExcludesNonMatches(t *testing.T) {
+	commands := []string{"echo hi", "ls -la", "pwd"}
+
+	got := filterAndSort(commands, "zzz", sortRecency)
+
+	if len(got) != 0 {
+		t.Fatalf("got %v, want no matches", got)
+	}
+}
@@ -0,0 +1,201 @@
+package finder
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/lemmyhemmingway/historik/store"
+)
+
+// ExternalFZF selects an entry by shelling out to the fzf binary on PATH.
+type ExternalFZF struct{}
+
+// FormatLine renders entry as a single fzf candidate line. Only the
+// command (field 1) is displayed and searched (see --with-nth/--nth in
+// Select); the remaining tab-separated fields carry the metadata the
+// preview window renders for the highlighted entry. It's also used by
+// historik's --internal-query mode, which fzf's reload() binds invoke to
+// repopulate the candidate list.
+//
+// Command and CWD are escaped so a literal tab or backslash in either
+// can't be mistaken for a field separator: without this, a command
+// containing a tab would split across fzf's own --delimiter-based field
+// boundaries, truncating both what's searched/displayed and what's
+// parsed back out of the selection.
+func FormatLine(entry store.HistoryEntry) string {
+	return strings.Join([]string{
+		escapeField(entry.Command),
+		strconv.FormatInt(entry.Timestamp.Unix(), 10),
+		escapeField(entry.CWD),
+		strconv.Itoa(entry.ExitCode),
+		strconv.FormatInt(entry.Duration.Milliseconds(), 10),
+	}, "\t")
+}
+
+// escapeField backslash-escapes the characters that would otherwise be
+// mistaken for fzf's tab field delimiter or for the escape character
+// itself.
+func escapeField(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// unescapeField reverses escapeField. It walks the string byte by byte
+// rather than chaining strings.ReplaceAll calls, since a naive
+// replace-in-sequence can't tell an escaped delimiter apart from a
+// literal backslash followed by a 't' that happened to already be in the
+// text.
+func unescapeField(s string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if escaped {
+			switch r {
+			case '\\':
+				b.WriteRune('\\')
+			case 't':
+				b.WriteRune('\t')
+			default:
+				b.WriteRune('\\')
+				b.WriteRune(r)
+			}
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		b.WriteRune(r)
+	}
+	if escaped {
+		b.WriteRune('\\')
+	}
+	return b.String()
+}
+
+// previewScript is run by fzf for the highlighted entry. It receives the
+// timestamp, cwd, exit code and duration fields as positional arguments
+// and renders them below the list, coloring the exit status red on
+// failure.
+const previewScript = `
+ts=$1; cwd=$2; code=$3; dur=$4
+if [ "$code" != "0" ]; then
+	printf "\033[31mexit: %s\033[0m\n" "$code"
+else
+	printf "exit: %s\n" "$code"
+fi
+if [ -n "$cwd" ]; then echo "cwd:  $cwd"; fi
+if [ "$ts" != "0" ]; then echo "time: $(date -d @"$ts" 2>/dev/null || date -r "$ts")"; fi
+if [ "$dur" != "0" ]; then echo "dur:  ${dur}ms"; fi
+`
+
+// Select pipes entries to fzf and returns the user's selection. Entries
+// are written to fzf's stdin as they arrive on the channel, mirroring
+// the producer/consumer pattern fzf's own shell integration uses, so fzf
+// becomes interactive immediately rather than after the whole history
+// has been read.
+func (ExternalFZF) Select(entries <-chan store.HistoryEntry) (string, error) {
+	if _, err := exec.LookPath("fzf"); err != nil {
+		return "", fmt.Errorf("fzf is not installed. Please install it to use this tool")
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "historik"
+	}
+
+	// Set up the FZF command with a custom prompt, border, and key bindings.
+	cmd := exec.Command("fzf",
+		"--height=40%",
+		"--reverse",
+		"--border",
+		"--prompt=Historik > ",
+		"--delimiter=\t",
+		"--with-nth=1",
+		"--nth=1",
+		"--preview", "bash -c '"+previewScript+"' _ {2} {3} {4} {5}",
+		"--preview-window=down:4:wrap",
+		"--bind=ctrl-r:toggle-sort",
+		fmt.Sprintf("--bind=ctrl-t:reload(%s --internal-query=today)", exe),
+		fmt.Sprintf("--bind=ctrl-g:reload(%s --internal-query=here)", exe),
+		fmt.Sprintf("--bind=ctrl-a:reload(%s --internal-query=all)", exe),
+		"--header=CTRL-R: toggle sort, CTRL-T: today, CTRL-G: here, CTRL-A: all, ESC: quit",
+	)
+	cmd.Stderr = os.Stderr
+
+	// Create a pipe to write the history to FZF's standard input.
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return "", fmt.Errorf("failed to create stdin pipe for fzf: %v", err)
+	}
+
+	// Capture the output of FZF.
+	output, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", fmt.Errorf("failed to create stdout pipe for fzf: %v", err)
+	}
+	defer output.Close()
+
+	// Start the FZF process.
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start fzf process: %v", err)
+	}
+
+	// Stream each history entry into FZF's stdin as it arrives, off in its
+	// own goroutine: entries is now backed by a live reverse disk scan
+	// rather than a pre-built slice, and fzf returns as soon as the user
+	// picks something, long before the scan reaches the front of a
+	// multi-MB history file. Writing stops the moment fzf does (the pipe
+	// breaks and WriteString starts erroring), instead of draining the
+	// channel to completion before historik is allowed to read fzf's
+	// selection.
+	go func() {
+		defer stdin.Close()
+		for entry := range entries {
+			if _, err := io.WriteString(stdin, FormatLine(entry)+"\n"); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Read the selected command from FZF's stdout.
+	selected, err := io.ReadAll(output)
+	if err != nil {
+		return "", fmt.Errorf("failed to read fzf output: %v", err)
+	}
+
+	// Wait for the FZF command to finish.
+	if err := cmd.Wait(); err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			// Exit code 130 is returned when the user cancels with ESC.
+			if exitError.ExitCode() == 130 {
+				return "", nil
+			}
+		}
+		return "", fmt.Errorf("fzf failed: %v", err)
+	}
+
+	// The selection still carries the hidden metadata fields; only the
+	// command (field 1) is meant to be executed, and it needs unescaping
+	// since FormatLine escaped any tabs/backslashes it contained.
+	fields := strings.SplitN(strings.TrimSpace(string(selected)), "\t", 2)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return unescapeField(fields[0]), nil
+}
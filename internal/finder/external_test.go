@@ -0,0 +1,48 @@
+package finder
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lemmyhemmingway/historik/store"
+)
+
+func TestEscapeFieldRoundTrip(t *testing.T) {
+	tests := []string{
+		"plain command",
+		"printf 'a\tb'",
+		`echo a\b`,
+		`echo a\tb`,
+		"trailing backslash\\",
+		"",
+	}
+	for _, in := range tests {
+		escaped := escapeField(in)
+		if strings.ContainsRune(escaped, '\t') {
+			t.Errorf("escapeField(%q) = %q still contains a raw tab", in, escaped)
+		}
+		if got := unescapeField(escaped); got != in {
+			t.Errorf("unescapeField(escapeField(%q)) = %q, want %q", in, got, in)
+		}
+	}
+}
+
+func TestFormatLineCommandSurvivesEmbeddedTab(t *testing.T) {
+	entry := store.HistoryEntry{
+		Timestamp: time.Unix(100, 0),
+		Command:   "printf 'a\tb'",
+		CWD:       "/tmp",
+	}
+
+	line := FormatLine(entry)
+
+	// This is the same split Select performs on fzf's stdout selection.
+	fields := strings.SplitN(line, "\t", 2)
+	if len(fields) != 2 {
+		t.Fatalf("FormatLine produced %d fields splitting on the first tab, want 2 (command, rest)", len(fields))
+	}
+	if got := unescapeField(fields[0]); got != entry.Command {
+		t.Errorf("parsed command = %q, want %q (it should survive the embedded tab intact)", got, entry.Command)
+	}
+}
@@ -0,0 +1,56 @@
+// Package finder selects a history entry interactively, either by
+// shelling out to fzf or, when fzf isn't available, with a small
+// embedded fuzzy finder.
+package finder
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/lemmyhemmingway/historik/store"
+)
+
+// Finder presents entries to the user and returns the selected command,
+// or "" if the user aborted the search.
+type Finder interface {
+	// Select consumes entries (newest-first) until the channel is
+	// closed or the user makes a selection. Backends that can act on
+	// results as they arrive (ExternalFZF) do so instead of waiting for
+	// the channel to drain.
+	Select(entries <-chan store.HistoryEntry) (string, error)
+}
+
+// Chan adapts a pre-materialized slice of entries (e.g. a store.Search
+// result) into the channel form Select expects.
+func Chan(entries []store.HistoryEntry) <-chan store.HistoryEntry {
+	out := make(chan store.HistoryEntry)
+	go func() {
+		defer close(out)
+		for _, entry := range entries {
+			out <- entry
+		}
+	}()
+	return out
+}
+
+// New returns the Finder for mode, which must be "auto", "fzf", or
+// "builtin". "auto" prefers fzf, falling back to the embedded finder
+// when fzf isn't on PATH.
+func New(mode string) (Finder, error) {
+	switch mode {
+	case "", "auto":
+		if _, err := exec.LookPath("fzf"); err != nil {
+			return Embedded{}, nil
+		}
+		return ExternalFZF{}, nil
+	case "fzf":
+		if _, err := exec.LookPath("fzf"); err != nil {
+			return nil, fmt.Errorf("fzf is not installed. Please install it, or pass --finder=builtin")
+		}
+		return ExternalFZF{}, nil
+	case "builtin":
+		return Embedded{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported finder %q (want auto, fzf, or builtin)", mode)
+	}
+}
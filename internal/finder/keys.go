@@ -0,0 +1,54 @@
+package finder
+
+import "bufio"
+
+// Key codes returned by readKey. Printable runes are returned as-is.
+const (
+	keyEnter     = '\r'
+	keyBackspace = 0x7f
+	keyCtrlC     = 0x03
+	keyCtrlR     = 0x12
+	keyEscape    = -1
+	keyUp        = -2
+	keyDown      = -3
+)
+
+// readKey reads a single keypress from r, translating the ANSI escape
+// sequences for the arrow keys and a bare Escape into the key* constants.
+func readKey(r *bufio.Reader) (int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	if b != 0x1b {
+		return int(b), nil
+	}
+
+	// Possibly an escape sequence (e.g. an arrow key); if no more bytes
+	// follow immediately, treat it as a bare Escape.
+	if r.Buffered() == 0 {
+		return keyEscape, nil
+	}
+
+	second, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if second != '[' {
+		return keyEscape, nil
+	}
+
+	third, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch third {
+	case 'A':
+		return keyUp, nil
+	case 'B':
+		return keyDown, nil
+	default:
+		return keyEscape, nil
+	}
+}
@@ -1,265 +1,373 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
-	"sort"
 	"strconv"
-	"strings"
 	"syscall"
 	"time"
+
+	"github.com/lemmyhemmingway/historik/config"
+	"github.com/lemmyhemmingway/historik/history"
+	"github.com/lemmyhemmingway/historik/internal/editline"
+	"github.com/lemmyhemmingway/historik/internal/finder"
+	"github.com/lemmyhemmingway/historik/store"
 )
 
-// HistoryEntry represents a single command from the Zsh history.
-type HistoryEntry struct {
-	Timestamp time.Time
-	Command   string
-}
+// internalQueryFlag is used by fzf's reload() binds to re-invoke historik
+// as a plain line source for a different filter mode (see --today/--here
+// below). It's not meant to be set directly by users.
+var internalQueryFlag = flag.String("internal-query", "", "internal: print entries for the given mode (today|here|all) and exit")
+
+// today and here select the initial filter mode; both can also be toggled
+// live from within fzf (see searchWithFZF).
+var (
+	todayFlag = flag.Bool("today", false, "only show commands run today")
+	// hereFlag only matches entries whose CWD was recorded, which only
+	// happens once a command has been run at least once through
+	// historik (see recordExecution); commands imported straight from
+	// the shell's history file never carry a cwd.
+	hereFlag   = flag.Bool("here", false, "only show commands previously run in the current directory through historik")
+	shellFlag  = flag.String("shell", "auto", "shell whose history to read: auto, zsh, bash, or fish")
+	finderFlag = flag.String("finder", "auto", "finder backend to use: auto, fzf, or builtin")
+)
 
 // main is the entry point of the program.
 func main() {
-	// 1. Find the Zsh history file.
-	historyFile := getZshHistoryFile()
-	if historyFile == "" {
-		fmt.Fprintf(os.Stderr, "Error: Zsh history file not found.\n")
-		fmt.Fprintf(os.Stderr, "Historik only supports zsh history and requires a non-empty HISTFILE environment variable or a default .zsh_history file.\n")
+	flag.Parse()
+
+	source, err := history.Detect(*shellFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// 2. Parse the history file into a slice of HistoryEntry structs.
-	entries, err := parseZshHistory(historyFile)
+	cfg, err := loadConfig()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Could not read history file: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: could not load config (%v), using defaults\n", err)
+		cfg = config.Default()
+	}
+
+	// 1. Find the shell's history file.
+	historyFile := source.HistoryFile()
+	if historyFile == "" {
+		fmt.Fprintf(os.Stderr, "Error: %s history file not found.\n", source.Name())
+		fmt.Fprintf(os.Stderr, "Historik requires a non-empty HISTFILE environment variable or a default history file for %s.\n", source.Name())
 		os.Exit(1)
 	}
 
-	if len(entries) == 0 {
+	db, importErr := openStore(historyFile)
+	if db != nil {
+		defer db.Close()
+	}
+
+	// fzf invokes historik again with --internal-query to repopulate its
+	// candidate list when the user toggles filter mode; handle that here
+	// and exit before doing anything interactive.
+	if *internalQueryFlag != "" {
+		runInternalQuery(db, cfg, *internalQueryFlag)
+		return
+	}
+
+	var (
+		entries   <-chan store.HistoryEntry
+		storeWarm bool
+	)
+	if db != nil {
+		if count, err := db.Count(); err == nil && count > 0 {
+			storeWarm = true
+			entries = finder.Chan(queryStore(db, *todayFlag, *hereFlag))
+		}
+	}
+
+	// Fall back to streaming the shell's own history file if the store
+	// is unavailable (e.g. sqlite couldn't be opened) or hasn't picked
+	// up anything yet. A store with rows but a filter that legitimately
+	// matched nothing is not a fallback case: an empty --today/--here
+	// result must stay empty, not silently widen to the whole history.
+	// Streaming means fzf starts receiving candidates immediately rather
+	// than after the whole file has been parsed.
+	if !storeWarm {
+		if importErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: history store unavailable (%v), falling back to flat-file history\n", importErr)
+		}
+		stream, err := source.Stream(historyFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Could not read history file: %v\n", err)
+			os.Exit(1)
+		}
+		entries = stream
+	}
+
+	uniqueEntries, empty := dedupeAndCheckEmpty(applyConfig(entries, cfg), cfg)
+	if empty {
 		fmt.Fprintf(os.Stderr, "History is empty\n")
 		os.Exit(1)
 	}
 
-	// 3. Remove duplicate commands, keeping only the most recent one.
-	uniqueEntries := removeDuplicates(entries)
-
-	// 4. Use FZF to allow the user to select a command from the unique history.
-	selectedCommand, err := searchWithFZF(uniqueEntries)
+	f, err := finder.New(*finderFlag)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// 5. If a command was selected, execute it.
-	if selectedCommand != "" {
-		executeCommand(selectedCommand)
+	// Let the user pick a command from the unique history.
+	selectedCommand, err := f.Select(uniqueEntries)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
-}
 
-// getZshHistoryFile locates the Zsh history file, checking HISTFILE first.
-func getZshHistoryFile() string {
-	// First check the HISTFILE environment variable.
-	if histfile := os.Getenv("HISTFILE"); histfile != "" {
-		if _, err := os.Stat(histfile); err == nil {
-			return histfile
-		}
+	if selectedCommand == "" {
+		return
 	}
 
-	// If HISTFILE is not set or doesn't exist, check the default location.
-	homeDir, err := os.UserHomeDir()
+	// Let the user review and tweak the selected command before it runs.
+	editor, err := editline.New()
 	if err != nil {
-		return ""
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
+	defer editor.Close()
 
-	defaultHistFile := filepath.Join(homeDir, ".zsh_history")
-	if _, err := os.Stat(defaultHistFile); err == nil {
-		return defaultHistFile
+	finalCommand, ok, err := editor.Edit(selectedCommand)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !ok {
+		return
 	}
 
-	return ""
+	executeCommand(source, db, finalCommand)
 }
 
-// parseZshHistory parses the Zsh history file, which can contain multi-line commands.
-func parseZshHistory(filename string) ([]HistoryEntry, error) {
-	file, err := os.Open(filename)
+// openStore opens the SQLite shadow history at $XDG_DATA_HOME/historik
+// (falling back to ~/.local/share/historik) and catches it up on any
+// history entries that have landed in histfile since the last run. It
+// returns a nil store (and a non-nil error) when the store can't be
+// opened or imported, so callers can fall back to the flat-file parser.
+func openStore(histfile string) (*store.Store, error) {
+	dataDir, err := historikDataDir()
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("locate data dir: %w", err)
 	}
-	defer file.Close()
-
-	var entries []HistoryEntry
-	scanner := bufio.NewScanner(file)
-	// Regex to match the Zsh extended history format: ": timestamp:elapsed;command"
-	extendedHistoryRegex := regexp.MustCompile(`^: (\d+):\d+;(.*)$`)
-
-	var currentEntry *HistoryEntry
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Attempt to match a new history entry with a timestamp.
-		if matches := extendedHistoryRegex.FindStringSubmatch(line); matches != nil {
-			// If a new entry is found, and there's a previous entry to save, save it.
-			if currentEntry != nil {
-				// Skip empty commands and the historik command itself.
-				if currentEntry.Command != "" && !strings.HasPrefix(currentEntry.Command, "historik") {
-					entries = append(entries, *currentEntry)
-				}
-			}
-
-			// Start a new history entry.
-			timestamp, err := strconv.ParseInt(matches[1], 10, 64)
-			var entryTimestamp time.Time
-			if err == nil {
-				entryTimestamp = time.Unix(timestamp, 0)
-			}
-			currentEntry = &HistoryEntry{
-				Timestamp: entryTimestamp,
-				Command:   strings.TrimSpace(matches[2]),
-			}
-		} else {
-			// This line is a continuation of the previous command.
-			if currentEntry != nil {
-				currentEntry.Command += "\n" + line
-			}
-		}
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
 	}
 
-	// Process the final entry after the loop finishes.
-	if currentEntry != nil {
-		if currentEntry.Command != "" && !strings.HasPrefix(currentEntry.Command, "historik") {
-			entries = append(entries, *currentEntry)
-		}
+	db, err := store.Open(filepath.Join(dataDir, "history.db"))
+	if err != nil {
+		return nil, err
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	importer := store.NewImporter(db, histfile)
+	if err := importer.ImportOnce(); err != nil {
+		return db, fmt.Errorf("import history: %w", err)
 	}
 
-	return entries, nil
+	return db, nil
 }
 
-// removeDuplicates removes duplicate commands, keeping the most recent one.
-func removeDuplicates(entries []HistoryEntry) []HistoryEntry {
-	seen := make(map[string]HistoryEntry)
-
-	// Iterate backwards to ensure the most recent entry is kept.
-	for i := len(entries) - 1; i >= 0; i-- {
-		entry := entries[i]
-		// Use the command as the key.
-		if _, exists := seen[entry.Command]; !exists {
-			seen[entry.Command] = entry
-		}
+// historikDataDir returns the directory historik stores its SQLite
+// database in, honoring XDG_DATA_HOME when set.
+func historikDataDir() (string, error) {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, "historik"), nil
 	}
-
-	// Convert the map back to a slice.
-	unique := make([]HistoryEntry, 0, len(seen))
-	for _, entry := range seen {
-		unique = append(unique, entry)
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
 	}
-
-	// Sort the slice by timestamp in reverse order (newest first).
-	sort.Slice(unique, func(i, j int) bool {
-		// Place entries with zero timestamps at the end.
-		if unique[i].Timestamp.IsZero() {
-			return false
-		}
-		if unique[j].Timestamp.IsZero() {
-			return true
-		}
-		return unique[i].Timestamp.After(unique[j].Timestamp)
-	})
-
-	return unique
+	return filepath.Join(home, ".local", "share", "historik"), nil
 }
 
-// searchWithFZF pipes the history to fzf and returns the user's selection.
-func searchWithFZF(entries []HistoryEntry) (string, error) {
-	// Check if FZF is installed.
-	if _, err := exec.LookPath("fzf"); err != nil {
-		return "", fmt.Errorf("fzf is not installed. Please install it to use this tool")
-	}
-
-	// Set up the FZF command with a custom prompt, border, and key bindings.
-	cmd := exec.Command("fzf",
-		"--height=40%",
-		"--reverse",
-		"--border",
-		"--prompt=Historik > ",
-		"--bind=ctrl-r:toggle-sort",
-		"--header=CTRL-R: toggle sort, ESC: quit",
-	)
-	cmd.Stderr = os.Stderr
+// queryStore runs a store.Search for the given filter mode, newest first.
+func queryStore(db *store.Store, today, here bool) []store.HistoryEntry {
+	var from, to time.Time
+	if today {
+		now := time.Now()
+		from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		to = now
+	}
 
-	// Create a pipe to write the history to FZF's standard input.
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return "", fmt.Errorf("failed to create stdin pipe for fzf: %v", err)
+	var cwd string
+	if here {
+		cwd, _ = os.Getwd()
 	}
-	defer stdin.Close()
 
-	// Capture the output of FZF.
-	output, err := cmd.StdoutPipe()
+	entries, err := db.Search("", "", from, to, cwd, 0)
 	if err != nil {
-		return "", fmt.Errorf("failed to create stdout pipe for fzf: %v", err)
+		fmt.Fprintf(os.Stderr, "Warning: history search failed: %v\n", err)
+		return nil
 	}
-	defer output.Close()
+	return entries
+}
 
-	// Start the FZF process.
-	if err := cmd.Start(); err != nil {
-		return "", fmt.Errorf("failed to start fzf process: %v", err)
+// runInternalQuery prints entries for the given mode as fzf candidate
+// lines. It's invoked by fzf's reload() binds when the user toggles
+// filter mode; see searchWithFZF.
+func runInternalQuery(db *store.Store, cfg *config.Config, mode string) {
+	if db == nil {
+		return
 	}
 
-	// Write each history command to FZF's stdin.
-	for _, entry := range entries {
-		io.WriteString(stdin, entry.Command+"\n")
+	var entries []store.HistoryEntry
+	switch mode {
+	case "today":
+		entries = queryStore(db, true, false)
+	case "here":
+		entries = queryStore(db, false, true)
+	default:
+		entries = queryStore(db, false, false)
 	}
 
-	// Close the stdin pipe to signal the end of input to FZF.
-	stdin.Close()
+	deduped, _ := dedupeAndCheckEmpty(applyConfig(finder.Chan(entries), cfg), cfg)
+	for entry := range deduped {
+		fmt.Println(finder.FormatLine(entry))
+	}
+}
 
-	// Read the selected command from FZF's stdout.
-	selected, err := io.ReadAll(output)
+// loadConfig loads historik's exclusion and redaction rules from
+// config.FilePath(), falling back to config.Default() if nothing is
+// configured.
+func loadConfig() (*config.Config, error) {
+	path, err := config.FilePath()
 	if err != nil {
-		return "", fmt.Errorf("failed to read fzf output: %v", err)
+		return nil, err
 	}
+	return config.Load(path)
+}
 
-	// Wait for the FZF command to finish.
-	if err := cmd.Wait(); err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			// Exit code 130 is returned when the user cancels with ESC.
-			if exitError.ExitCode() == 130 {
-				return "", nil
+// applyConfig drops entries cfg excludes and redacts secrets out of the
+// rest, before they ever reach dedupe or the finder.
+func applyConfig(in <-chan store.HistoryEntry, cfg *config.Config) <-chan store.HistoryEntry {
+	out := make(chan store.HistoryEntry)
+	go func() {
+		defer close(out)
+		for entry := range in {
+			if cfg.ShouldExclude(entry.Command, entry.CWD) {
+				continue
+			}
+			entry.Command = cfg.Redact(entry.Command)
+			out <- entry
+		}
+	}()
+	return out
+}
+
+// dedupe filters out commands already seen earlier on the channel,
+// keeping only their first (and since entries arrive newest-first, most
+// recent) occurrence, as compared under cfg's configured dedupe mode. It
+// mirrors the producer/consumer pattern fzf's own shell integration
+// uses: a dedup stage sitting between the history source and the writer
+// that feeds the finder.
+func dedupe(in <-chan store.HistoryEntry, cfg *config.Config) <-chan store.HistoryEntry {
+	out := make(chan store.HistoryEntry)
+	go func() {
+		defer close(out)
+		seen := make(map[string]struct{})
+		for entry := range in {
+			key := cfg.DedupeKey(entry.Command)
+			if _, ok := seen[key]; ok {
+				continue
 			}
+			seen[key] = struct{}{}
+			out <- entry
 		}
-		return "", fmt.Errorf("fzf failed: %v", err)
+	}()
+	return out
+}
+
+// dedupeAndCheckEmpty dedupes in and reports whether it turned out to be
+// empty, without requiring the whole channel to be drained first.
+func dedupeAndCheckEmpty(in <-chan store.HistoryEntry, cfg *config.Config) (out <-chan store.HistoryEntry, empty bool) {
+	deduped := dedupe(in, cfg)
+	first, ok := <-deduped
+	if !ok {
+		return deduped, true
 	}
 
-	return strings.TrimSpace(string(selected)), nil
+	merged := make(chan store.HistoryEntry)
+	go func() {
+		defer close(merged)
+		merged <- first
+		for entry := range deduped {
+			merged <- entry
+		}
+	}()
+	return merged, false
 }
 
-// executeCommand executes the selected command in a new Zsh shell.
-func executeCommand(command string) {
-	// Use `zsh -c` to execute the command, which respects shell features like aliases.
-	cmd := exec.Command("zsh", "-c", command)
+// executeCommand executes the selected command via source's interpreter
+// and, if db is non-nil, records its cwd, exit code, and duration back
+// into the store. This is the only place historik can actually observe
+// that metadata: the shell's own history file never carries it, so an
+// imported entry's CWD/ExitCode/Duration stay at their zero values until
+// the command is re-run through historik at least once.
+func executeCommand(source history.Source, db *store.Store, command string) {
+	argv := source.Command(command)
+	cmd := exec.Command(argv[0], argv[1:]...)
 
 	// Connect all I/O to the current process. This allows the user to interact with the command.
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
+	start := time.Now()
+	cwd, _ := os.Getwd()
+
 	// Execute the command and wait for it to complete.
 	err := cmd.Run()
+
+	exitCode := 0
 	if err != nil {
-		// Propagate the exit code if the command fails.
+		exitCode = 1
 		if exitError, ok := err.(*exec.ExitError); ok {
 			if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
-				os.Exit(status.ExitStatus())
+				exitCode = status.ExitStatus()
 			}
 		}
-		os.Exit(1)
 	}
+
+	recordExecution(db, command, cwd, exitCode, time.Since(start), start)
+
+	if err != nil {
+		os.Exit(exitCode)
+	}
+}
+
+// recordExecution upserts the metadata historik observed while running
+// command, so it's searchable (by --here, by exit status, ...) the next
+// time historik starts. It's a best-effort write: a nil db or a failed
+// upsert must never stop the command the user just ran from returning
+// control normally.
+func recordExecution(db *store.Store, command, cwd string, exitCode int, duration time.Duration, at time.Time) {
+	if db == nil {
+		return
+	}
+	hostname, _ := os.Hostname()
+	entry := store.HistoryEntry{
+		Timestamp: at,
+		Command:   command,
+		CWD:       cwd,
+		ExitCode:  exitCode,
+		Hostname:  hostname,
+		SessionID: sessionID(),
+		Duration:  duration,
+	}
+	if err := db.Upsert(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record command metadata: %v\n", err)
+	}
+}
+
+// sessionID identifies the invoking shell so entries from the same
+// terminal session can be grouped. historik isn't spawned by a long-lived
+// hook, so the closest stable handle it has to "this shell" is its
+// parent process ID.
+func sessionID() string {
+	return strconv.Itoa(os.Getppid())
 }
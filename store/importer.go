@@ -0,0 +1,148 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// extendedHistoryRegex matches the Zsh extended history format:
+// ": timestamp:elapsed;command"
+var extendedHistoryRegex = regexp.MustCompile(`^: (\d+):\d+;(.*)$`)
+
+// Importer tails a Zsh history file and upserts new entries into a Store
+// as they're appended, so the SQLite shadow stays current without a
+// separate import step.
+type Importer struct {
+	store    *Store
+	histfile string
+	hostname string
+
+	// offset is where the next read begins. It always rewinds to the
+	// start of the last entry seen so far, so that entry is re-parsed
+	// (and harmlessly re-upserted, since Upsert is idempotent) in case
+	// more continuation lines were appended to it since.
+	offset int64
+}
+
+// NewImporter returns an Importer that tails histfile into store.
+func NewImporter(s *Store, histfile string) *Importer {
+	hostname, _ := os.Hostname()
+	return &Importer{store: s, histfile: histfile, hostname: hostname}
+}
+
+// ImportOnce reads whatever is new in histfile since the last call and
+// upserts it into the store. It's cheap to call on every invocation of
+// historik to catch the store up before a search.
+func (im *Importer) ImportOnce() error {
+	return im.importNewLines()
+}
+
+// Run calls ImportOnce every interval until ctx is cancelled, for use by
+// a long-lived tailer process rather than a one-shot CLI invocation.
+func (im *Importer) Run(ctx context.Context, interval time.Duration) error {
+	if err := im.ImportOnce(); err != nil {
+		return fmt.Errorf("initial import: %w", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := im.ImportOnce(); err != nil {
+				return fmt.Errorf("tail import: %w", err)
+			}
+		}
+	}
+}
+
+// importNewLines reads from im.offset to EOF, upserting every complete
+// entry it finds, then rewinds the offset to the start of the final
+// (possibly still-growing) entry.
+func (im *Importer) importNewLines() error {
+	file, err := os.Open(im.histfile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	// The file was truncated or replaced (e.g. `history -c`); start over.
+	if info.Size() < im.offset {
+		im.offset = 0
+	}
+
+	if _, err := file.Seek(im.offset, 0); err != nil {
+		return err
+	}
+
+	var (
+		scanner      = bufio.NewScanner(file)
+		currentEntry *HistoryEntry
+		lineStart    = im.offset
+		entryStart   = im.offset
+	)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if matches := extendedHistoryRegex.FindStringSubmatch(line); matches != nil {
+			if currentEntry != nil {
+				if err := im.upsertIfReal(*currentEntry); err != nil {
+					return err
+				}
+			}
+			entryStart = lineStart
+
+			unixTime, _ := strconv.ParseInt(matches[1], 10, 64)
+			currentEntry = &HistoryEntry{
+				Timestamp: time.Unix(unixTime, 0),
+				Command:   strings.TrimSpace(matches[2]),
+				Hostname:  im.hostname,
+			}
+		} else if currentEntry != nil {
+			currentEntry.Command += "\n" + line
+		}
+
+		lineStart += int64(len(line)) + 1
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// Flush the final entry too. It's still re-verified next run (offset
+	// rewinds to entryStart below), so upserting it now is safe even if
+	// more continuation lines land on it later.
+	if currentEntry != nil {
+		if err := im.upsertIfReal(*currentEntry); err != nil {
+			return err
+		}
+	}
+
+	im.offset = entryStart
+	return nil
+}
+
+// upsertIfReal upserts entry unless it's empty. Filtering out anything
+// more specific than that (historik recalling its own invocation,
+// user-defined exclude rules, ...) is config.Config's job, applied to
+// whatever the store returns rather than at import time, so changing a
+// rule doesn't require re-importing history to take effect.
+func (im *Importer) upsertIfReal(entry HistoryEntry) error {
+	if entry.Command == "" {
+		return nil
+	}
+	return im.store.Upsert(entry)
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeHistFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "histfile")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestImportOnceFlushesTrailingEntry(t *testing.T) {
+	// A regression test for the trailing entry only being upserted once a
+	// later entry's header line appeared: the final command in the file
+	// must be imported on the very first pass, not just once a fourth
+	// command is appended after it.
+	hist := writeHistFile(t, ": 100:0;echo one\n: 200:0;echo two\n: 300:0;echo three\n")
+	s := openTestStore(t)
+
+	imp := NewImporter(s, hist)
+	if err := imp.ImportOnce(); err != nil {
+		t.Fatalf("ImportOnce: %v", err)
+	}
+
+	entries, err := s.Search("", "", time.Time{}, time.Time{}, "", 0)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	var commands []string
+	for _, e := range entries {
+		commands = append(commands, e.Command)
+	}
+	want := []string{"echo three", "echo two", "echo one"}
+	if !equalStrings(commands, want) {
+		t.Fatalf("commands = %v, want %v", commands, want)
+	}
+}
+
+func TestImportOnceIsIncremental(t *testing.T) {
+	hist := writeHistFile(t, ": 100:0;echo one\n: 200:0;echo two\n")
+	s := openTestStore(t)
+	imp := NewImporter(s, hist)
+
+	if err := imp.ImportOnce(); err != nil {
+		t.Fatalf("ImportOnce #1: %v", err)
+	}
+	if count, _ := s.Count(); count != 2 {
+		t.Fatalf("count after first import = %d, want 2", count)
+	}
+
+	f, err := os.OpenFile(hist, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	if _, err := f.WriteString(": 300:0;echo three\n"); err != nil {
+		t.Fatalf("WriteString: %v", err)
+	}
+	f.Close()
+
+	if err := imp.ImportOnce(); err != nil {
+		t.Fatalf("ImportOnce #2: %v", err)
+	}
+	if count, _ := s.Count(); count != 3 {
+		t.Fatalf("count after second import = %d, want 3", count)
+	}
+}
+
+func TestImportOnceHandlesMultilineCommand(t *testing.T) {
+	hist := writeHistFile(t, ": 100:0;echo one\nline two\nline three\n: 200:0;echo two\n")
+	s := openTestStore(t)
+	imp := NewImporter(s, hist)
+
+	if err := imp.ImportOnce(); err != nil {
+		t.Fatalf("ImportOnce: %v", err)
+	}
+
+	entries, err := s.Search("", "", time.Time{}, time.Time{}, "", 0)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	want := "echo one\nline two\nline three"
+	if entries[1].Command != want {
+		t.Errorf("multiline command = %q, want %q", entries[1].Command, want)
+	}
+}
+
+func TestImportOnceDoesNotFilterHistorikSelfInvocations(t *testing.T) {
+	// Excluding historik's own invocations is config.Config's job, applied
+	// to whatever the store returns, not the importer's, so both entries
+	// land in the store here.
+	hist := writeHistFile(t, ": 100:0;historik --today\n: 200:0;echo real\n")
+	s := openTestStore(t)
+	imp := NewImporter(s, hist)
+
+	if err := imp.ImportOnce(); err != nil {
+		t.Fatalf("ImportOnce: %v", err)
+	}
+
+	count, err := s.Count()
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("count = %d, want 2", count)
+	}
+}
@@ -0,0 +1,181 @@
+// Package store provides a persistent SQLite-backed shadow of the shell
+// history file, enriched with metadata the flat history file doesn't carry
+// (working directory, exit status, host, session) and indexed for fast
+// range queries on multi-year histories.
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// HistoryEntry is a single recorded command, enriched with the metadata
+// historik tracks beyond what the shell's own history file stores. The
+// shell's history file never carries CWD, ExitCode, SessionID, or
+// Duration, so entries imported from it only get those fields filled in
+// once the command is selected and re-run through historik itself (see
+// recordExecution in main.go); until then they're zero-valued.
+type HistoryEntry struct {
+	Timestamp time.Time
+	Command   string
+	CWD       string
+	ExitCode  int
+	Hostname  string
+	SessionID string
+	Duration  time.Duration
+}
+
+// Store wraps a SQLite database holding the history table.
+type Store struct {
+	db *sql.DB
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS history (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp   INTEGER NOT NULL,
+	command     TEXT NOT NULL,
+	cwd         TEXT NOT NULL DEFAULT '',
+	exit_code   INTEGER NOT NULL DEFAULT 0,
+	hostname    TEXT NOT NULL DEFAULT '',
+	session_id  TEXT NOT NULL DEFAULT '',
+	duration_ms INTEGER NOT NULL DEFAULT 0,
+	UNIQUE(timestamp, command, hostname)
+);
+CREATE INDEX IF NOT EXISTS idx_history_timestamp ON history(timestamp);
+CREATE INDEX IF NOT EXISTS idx_history_cwd ON history(cwd);
+`
+
+// Open opens (creating if necessary) the SQLite database at path and
+// ensures the history table exists.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Upsert inserts entry into the history table, ignoring it if an identical
+// (timestamp, command, hostname) row already exists so that re-importing
+// the same history file is idempotent.
+func (s *Store) Upsert(entry HistoryEntry) error {
+	_, err := s.db.Exec(
+		`INSERT OR IGNORE INTO history (timestamp, command, cwd, exit_code, hostname, session_id, duration_ms)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		entry.Timestamp.Unix(), entry.Command, entry.CWD, entry.ExitCode,
+		entry.Hostname, entry.SessionID, entry.Duration.Milliseconds(),
+	)
+	if err != nil {
+		return fmt.Errorf("upsert entry: %w", err)
+	}
+	return nil
+}
+
+// Count returns the total number of rows in the history table,
+// regardless of any filter. Callers use this to tell "the store hasn't
+// been populated yet" apart from "a filtered search legitimately
+// matched nothing".
+func (s *Store) Count() (int, error) {
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM history`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count: %w", err)
+	}
+	return count, nil
+}
+
+// Search returns history entries matching the given filters, newest first.
+//
+// prefix, if non-empty, restricts results to commands starting with it.
+// pattern, if non-empty, restricts results to commands containing it
+// anywhere. from/to restrict the entry's timestamp to the given range when
+// non-zero. cwd, if non-empty, restricts results to entries recorded in
+// that directory. limit caps the number of rows returned; a value <= 0
+// means unlimited.
+func (s *Store) Search(prefix, pattern string, from, to time.Time, cwd string, limit int) ([]HistoryEntry, error) {
+	query := `SELECT timestamp, command, cwd, exit_code, hostname, session_id, duration_ms
+	          FROM history WHERE 1=1`
+	var args []interface{}
+
+	if prefix != "" {
+		query += " AND command LIKE ? ESCAPE '\\'"
+		args = append(args, escapeLike(prefix)+"%")
+	}
+	if pattern != "" {
+		query += " AND command LIKE ? ESCAPE '\\'"
+		args = append(args, "%"+escapeLike(pattern)+"%")
+	}
+	if !from.IsZero() {
+		query += " AND timestamp >= ?"
+		args = append(args, from.Unix())
+	}
+	if !to.IsZero() {
+		query += " AND timestamp <= ?"
+		args = append(args, to.Unix())
+	}
+	if cwd != "" {
+		query += " AND cwd = ?"
+		args = append(args, cwd)
+	}
+
+	query += " ORDER BY timestamp DESC"
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	defer rows.Close()
+
+	var results []HistoryEntry
+	for rows.Next() {
+		var (
+			entry      HistoryEntry
+			unixTime   int64
+			durationMs int64
+		)
+		if err := rows.Scan(&unixTime, &entry.Command, &entry.CWD, &entry.ExitCode,
+			&entry.Hostname, &entry.SessionID, &durationMs); err != nil {
+			return nil, fmt.Errorf("scan entry: %w", err)
+		}
+		entry.Timestamp = time.Unix(unixTime, 0)
+		entry.Duration = time.Duration(durationMs) * time.Millisecond
+		results = append(results, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	return results, nil
+}
+
+// escapeLike escapes the LIKE wildcard characters in s so that literal
+// user input can be safely embedded in a LIKE pattern.
+func escapeLike(s string) string {
+	r := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '%', '_', '\\':
+			r = append(r, '\\')
+		}
+		r = append(r, s[i])
+	}
+	return string(r)
+}
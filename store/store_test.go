@@ -0,0 +1,144 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestUpsertIsIdempotent(t *testing.T) {
+	s := openTestStore(t)
+	entry := HistoryEntry{
+		Timestamp: time.Unix(100, 0),
+		Command:   "echo hi",
+		Hostname:  "host",
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := s.Upsert(entry); err != nil {
+			t.Fatalf("Upsert #%d: %v", i, err)
+		}
+	}
+
+	count, err := s.Count()
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("count = %d, want 1 (duplicate upserts should be ignored)", count)
+	}
+}
+
+func TestSearchFilters(t *testing.T) {
+	s := openTestStore(t)
+	entries := []HistoryEntry{
+		{Timestamp: time.Unix(100, 0), Command: "git status", CWD: "/a", Hostname: "h"},
+		{Timestamp: time.Unix(200, 0), Command: "git commit -m wip", CWD: "/b", Hostname: "h"},
+		{Timestamp: time.Unix(300, 0), Command: "ls -la", CWD: "/a", Hostname: "h"},
+	}
+	for _, e := range entries {
+		if err := s.Upsert(e); err != nil {
+			t.Fatalf("Upsert: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		prefix       string
+		pattern      string
+		from, to     time.Time
+		cwd          string
+		limit        int
+		wantCommands []string
+	}{
+		{
+			name:         "no filter returns newest first",
+			wantCommands: []string{"ls -la", "git commit -m wip", "git status"},
+		},
+		{
+			name:         "prefix",
+			prefix:       "git",
+			wantCommands: []string{"git commit -m wip", "git status"},
+		},
+		{
+			name:         "pattern substring",
+			pattern:      "commit",
+			wantCommands: []string{"git commit -m wip"},
+		},
+		{
+			name:         "cwd",
+			cwd:          "/a",
+			wantCommands: []string{"ls -la", "git status"},
+		},
+		{
+			name:         "time range",
+			from:         time.Unix(150, 0),
+			to:           time.Unix(250, 0),
+			wantCommands: []string{"git commit -m wip"},
+		},
+		{
+			name:         "limit",
+			limit:        1,
+			wantCommands: []string{"ls -la"},
+		},
+		{
+			name:         "pattern with like wildcard is literal",
+			pattern:      "%",
+			wantCommands: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.Search(tc.prefix, tc.pattern, tc.from, tc.to, tc.cwd, tc.limit)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			var commands []string
+			for _, e := range got {
+				commands = append(commands, e.Command)
+			}
+			if !equalStrings(commands, tc.wantCommands) {
+				t.Errorf("commands = %v, want %v", commands, tc.wantCommands)
+			}
+		})
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"50%", `50\%`},
+		{"a_b", `a\_b`},
+		{`back\slash`, `back\\slash`},
+	}
+	for _, tc := range tests {
+		if got := escapeLike(tc.in); got != tc.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}